@@ -0,0 +1,118 @@
+package breaker
+
+import (
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/stringx"
+)
+
+// ErrServiceUnavailable is returned when the Breaker state is open.
+var ErrServiceUnavailable = errors.New("circuit breaker is open")
+
+type (
+	// Acceptable is the func to check if the error can be accepted.
+	Acceptable func(err error) bool
+
+	// Fallback is used when the Breaker state is open.
+	Fallback func(err error) error
+
+	// Promise interface defines the callbacks that are used in Breaker.
+	Promise interface {
+		// Accept tells the Breaker that the call is successful.
+		Accept()
+		// Reject tells the Breaker that the call is failed.
+		Reject()
+	}
+
+	internalPromise interface {
+		Accept()
+		Reject()
+	}
+
+	throttle interface {
+		allow() (internalPromise, error)
+		doReq(req func() error, fallback Fallback, acceptable Acceptable) error
+	}
+
+	// Breaker represents a circuit breaker.
+	Breaker interface {
+		// Name returns the name of the Breaker.
+		Name() string
+		// Allow checks if the request is allowed. If allowed, a Promise is
+		// returned and the caller must call Accept() on success or Reject()
+		// on failure. If not allowed, ErrServiceUnavailable is returned.
+		Allow() (Promise, error)
+		// Do runs req if the Breaker accepts it, returning ErrServiceUnavailable
+		// instantly if the Breaker rejects it.
+		Do(req func() error) error
+		// DoWithAcceptable is like Do, but treats a returned error as a success
+		// if acceptable says so.
+		DoWithAcceptable(req func() error, acceptable Acceptable) error
+		// DoWithFallback is like Do, but runs fallback instead of returning
+		// ErrServiceUnavailable when the Breaker rejects the call.
+		DoWithFallback(req func() error, fallback Fallback) error
+		// DoWithFallbackAcceptable combines DoWithFallback and DoWithAcceptable.
+		DoWithFallbackAcceptable(req func() error, fallback Fallback, acceptable Acceptable) error
+	}
+
+	// Option customizes a Breaker created by NewBreaker.
+	Option func(breaker *breaker)
+
+	breaker struct {
+		name       string
+		googleOpts []googleBreakerOption
+		throttle
+	}
+)
+
+// NewBreaker returns a Breaker, configured by opts. WithStatsListener and
+// WithHalfOpen customize the underlying googleBreaker; WithName sets the
+// Breaker's name.
+func NewBreaker(opts ...Option) Breaker {
+	var b breaker
+	for _, opt := range opts {
+		opt(&b)
+	}
+	if len(b.name) == 0 {
+		b.name = stringx.Rand()
+	}
+
+	b.throttle = newGoogleBreaker(b.googleOpts...)
+
+	return &b
+}
+
+// WithName returns an Option that sets the name for a Breaker.
+func WithName(name string) Option {
+	return func(b *breaker) {
+		b.name = name
+	}
+}
+
+func (b *breaker) Name() string {
+	return b.name
+}
+
+func (b *breaker) Allow() (Promise, error) {
+	return b.throttle.allow()
+}
+
+func (b *breaker) Do(req func() error) error {
+	return b.throttle.doReq(req, nil, defaultAcceptable)
+}
+
+func (b *breaker) DoWithAcceptable(req func() error, acceptable Acceptable) error {
+	return b.throttle.doReq(req, nil, acceptable)
+}
+
+func (b *breaker) DoWithFallback(req func() error, fallback Fallback) error {
+	return b.throttle.doReq(req, fallback, defaultAcceptable)
+}
+
+func (b *breaker) DoWithFallbackAcceptable(req func() error, fallback Fallback, acceptable Acceptable) error {
+	return b.throttle.doReq(req, fallback, acceptable)
+}
+
+func defaultAcceptable(err error) bool {
+	return err == nil
+}
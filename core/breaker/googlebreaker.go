@@ -1,6 +1,7 @@
 package breaker
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/collection"
@@ -17,6 +18,15 @@ const (
 	k                 = 1.5
 	minK              = 1.1
 	protection        = 5
+	// halfOpenTripBuckets is the number of consecutive failing buckets after
+	// which the breaker trips into the half-open probe state, instead of
+	// relying solely on forcePassDuration/dropRatio scaling to recover.
+	halfOpenTripBuckets = 5
+)
+
+const (
+	stateClosed int32 = iota
+	stateHalfOpen
 )
 
 // googleBreaker is a netflixBreaker pattern from google.
@@ -27,40 +37,68 @@ type (
 		stat     *collection.RollingWindow[int64, *bucket]
 		proba    *mathx.Proba
 		lastPass *syncx.AtomicDuration
+		listener StatsListener
+
+		// half-open probe mode, see WithHalfOpen. maxProbes == 0 means the
+		// feature is disabled and recovery behaves as before.
+		maxProbes            int32
+		requiredSuccesses    int32
+		state                int32
+		inFlightProbes       int32
+		consecutiveSuccesses int32
 	}
 
 	windowResult struct {
-		accepts        int64
-		total          int64
-		failingBuckets int64
-		workingBuckets int64
+		Accepts        int64
+		Total          int64
+		FailingBuckets int64
+		WorkingBuckets int64
 	}
 )
 
-func newGoogleBreaker() *googleBreaker {
+func newGoogleBreaker(opts ...googleBreakerOption) *googleBreaker {
 	bucketDuration := time.Duration(int64(window) / int64(buckets))
 	st := collection.NewRollingWindow[int64, *bucket](func() *bucket {
 		return new(bucket)
 	}, buckets, bucketDuration)
-	return &googleBreaker{
+	b := &googleBreaker{
 		stat:     st,
 		k:        k,
 		proba:    mathx.NewProba(),
 		lastPass: syncx.NewAtomicDuration(),
 	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
 }
 
 func (b *googleBreaker) accept() error {
+	if b.maxProbes > 0 && atomic.LoadInt32(&b.state) == stateHalfOpen {
+		return b.acceptProbe()
+	}
+
 	var w float64
 	history := b.history()
+
+	if b.maxProbes > 0 && history.FailingBuckets >= halfOpenTripBuckets &&
+		atomic.CompareAndSwapInt32(&b.state, stateClosed, stateHalfOpen) {
+		atomic.StoreInt32(&b.inFlightProbes, 0)
+		atomic.StoreInt32(&b.consecutiveSuccesses, 0)
+		return b.acceptProbe()
+	}
+
 	// 根据连续失败的bucket数调整权重, w ∈ [minK, k]
 	// 失败的bucket数越多, w越小, 降低接受请求的概率
-	w = b.k - (b.k-minK)*float64(history.failingBuckets)/buckets
-	weightedAccepts := mathx.AtLeast(w, minK) * float64(history.accepts)
+	w = b.k - (b.k-minK)*float64(history.FailingBuckets)/buckets
+	weightedAccepts := mathx.AtLeast(w, minK) * float64(history.Accepts)
 	// https://landing.google.com/sre/sre-book/chapters/handling-overload/#eq2101
 	// for better performance, no need to care about the negative ratio
-	dropRatio := (float64(history.total-protection) - weightedAccepts) / float64(history.total+1)
+	dropRatio := (float64(history.Total-protection) - weightedAccepts) / float64(history.Total+1)
 	if dropRatio <= 0 {
+		b.reportStats(history, dropRatio, false, OutcomePass)
 		return nil
 	}
 
@@ -68,21 +106,76 @@ func (b *googleBreaker) accept() error {
 	// 如果距离上次通过请求的时间超过forcePassDuration, 则强制通过请求
 	if lastPass > 0 && timex.Since(lastPass) > forcePassDuration {
 		b.lastPass.Set(timex.Now())
+		b.reportStats(history, dropRatio, true, OutcomePass)
 		return nil
 	}
 
 	// 当存在成功的bucket时, 说明系统正在恢复, 逐步增加接受请求的概率(降低dropRatio)
-	dropRatio *= float64(buckets-history.workingBuckets) / buckets
+	dropRatio *= float64(buckets-history.WorkingBuckets) / buckets
 
 	if b.proba.TrueOnProba(dropRatio) {
+		b.reportStats(history, dropRatio, false, OutcomeDrop)
 		return ErrServiceUnavailable
 	}
 
 	b.lastPass.Set(timex.Now())
+	b.reportStats(history, dropRatio, false, OutcomePass)
 
 	return nil
 }
 
+// reportStats notifies the configured StatsListener, if any, of the decision
+// that was just made by accept(), or of a panic in doReq. It is a no-op when
+// no listener is set.
+func (b *googleBreaker) reportStats(history windowResult, dropRatio float64, lastPass bool, outcome Outcome) {
+	if b.listener == nil {
+		return
+	}
+
+	b.listener.OnStats(StatsResult{
+		Window:    history,
+		DropRatio: dropRatio,
+		LastPass:  lastPass,
+		Outcome:   outcome,
+	})
+}
+
+// acceptProbe admits at most maxProbes concurrent in-flight calls while the
+// breaker is half-open, rejecting everything beyond that so recovery doesn't
+// turn into a thundering herd of retries.
+func (b *googleBreaker) acceptProbe() error {
+	if atomic.AddInt32(&b.inFlightProbes, 1) > b.maxProbes {
+		atomic.AddInt32(&b.inFlightProbes, -1)
+		b.reportStats(windowResult{}, 0, false, OutcomeDrop)
+		return ErrServiceUnavailable
+	}
+
+	b.reportStats(windowResult{}, 0, false, OutcomePass)
+
+	return nil
+}
+
+// onProbeResult accounts for the outcome of a half-open probe. M consecutive
+// successes close the breaker again; any failure reverts to throttling.
+func (b *googleBreaker) onProbeResult(success bool) {
+	if b.maxProbes == 0 || atomic.LoadInt32(&b.state) != stateHalfOpen {
+		return
+	}
+
+	atomic.AddInt32(&b.inFlightProbes, -1)
+
+	if !success {
+		atomic.StoreInt32(&b.consecutiveSuccesses, 0)
+		atomic.StoreInt32(&b.state, stateClosed)
+		return
+	}
+
+	if atomic.AddInt32(&b.consecutiveSuccesses, 1) >= b.requiredSuccesses {
+		atomic.StoreInt32(&b.state, stateClosed)
+		atomic.StoreInt32(&b.consecutiveSuccesses, 0)
+	}
+}
+
 func (b *googleBreaker) allow() (internalPromise, error) {
 	if err := b.accept(); err != nil {
 		b.markDrop()
@@ -106,11 +199,20 @@ func (b *googleBreaker) doReq(req func() error, fallback Fallback, acceptable Ac
 
 	var succ bool
 	defer func() {
+		if p := recover(); p != nil {
+			b.markFailure()
+			b.onProbeResult(false)
+			b.reportStats(b.history(), 0, false, OutcomePanic)
+			panic(p)
+		}
+
 		// if req() panic, success is false, mark as failure
 		if succ {
 			b.markSuccess()
+			b.onProbeResult(true)
 		} else {
 			b.markFailure()
+			b.onProbeResult(false)
 		}
 	}()
 
@@ -138,19 +240,19 @@ func (b *googleBreaker) history() windowResult {
 	var result windowResult
 
 	b.stat.Reduce(func(b *bucket) {
-		result.accepts += b.Success
-		result.total += b.Sum
+		result.Accepts += b.Success
+		result.Total += b.Sum
 
 		// 计算当前连续成功/失败的bucket数
 		if b.Failure > 0 {
-			result.workingBuckets = 0
+			result.WorkingBuckets = 0
 		} else if b.Success > 0 {
-			result.workingBuckets++ // 连续成功的bucket数
+			result.WorkingBuckets++ // 连续成功的bucket数
 		}
 		if b.Success > 0 {
-			result.failingBuckets = 0
+			result.FailingBuckets = 0
 		} else if b.Failure > 0 {
-			result.failingBuckets++ // 连续失败的bucket数
+			result.FailingBuckets++ // 连续失败的bucket数
 		}
 	})
 
@@ -163,8 +265,10 @@ type googlePromise struct {
 
 func (p googlePromise) Accept() {
 	p.b.markSuccess()
+	p.b.onProbeResult(true)
 }
 
 func (p googlePromise) Reject() {
 	p.b.markFailure()
+	p.b.onProbeResult(false)
 }
@@ -0,0 +1,65 @@
+package breaker
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newHalfOpenBreaker(maxProbes, requiredSuccesses int32) *googleBreaker {
+	b := newGoogleBreaker()
+	b.maxProbes = maxProbes
+	b.requiredSuccesses = requiredSuccesses
+	atomic.StoreInt32(&b.state, stateHalfOpen)
+	return b
+}
+
+func TestGoogleBreaker_AcceptProbeBoundsConcurrency(t *testing.T) {
+	b := newHalfOpenBreaker(2, 1)
+
+	assert.NoError(t, b.acceptProbe())
+	assert.NoError(t, b.acceptProbe())
+	assert.Equal(t, ErrServiceUnavailable, b.acceptProbe())
+}
+
+func TestGoogleBreaker_OnProbeResultClosesAfterRequiredSuccesses(t *testing.T) {
+	b := newHalfOpenBreaker(2, 2)
+
+	assert.NoError(t, b.acceptProbe())
+	assert.NoError(t, b.acceptProbe())
+
+	b.onProbeResult(true)
+	assert.Equal(t, stateHalfOpen, atomic.LoadInt32(&b.state))
+
+	b.onProbeResult(true)
+	assert.Equal(t, stateClosed, atomic.LoadInt32(&b.state))
+}
+
+func TestGoogleBreaker_OnProbeResultRevertsOnFailure(t *testing.T) {
+	b := newHalfOpenBreaker(2, 2)
+
+	assert.NoError(t, b.acceptProbe())
+	b.onProbeResult(false)
+
+	assert.Equal(t, stateClosed, atomic.LoadInt32(&b.state))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&b.consecutiveSuccesses))
+}
+
+func TestGoogleBreaker_OnProbeResultNoopWhenDisabled(t *testing.T) {
+	b := newGoogleBreaker()
+
+	// half-open is disabled (maxProbes == 0), so onProbeResult must not panic
+	// or mutate state that was never initialized for probing.
+	b.onProbeResult(true)
+	assert.Equal(t, stateClosed, atomic.LoadInt32(&b.state))
+}
+
+func TestGoogleBreaker_WithHalfOpenOption(t *testing.T) {
+	var br breaker
+	WithHalfOpen(3, 2)(&br)
+	b := newGoogleBreaker(br.googleOpts...)
+
+	assert.Equal(t, int32(3), b.maxProbes)
+	assert.Equal(t, int32(2), b.requiredSuccesses)
+}
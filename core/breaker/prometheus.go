@@ -0,0 +1,60 @@
+package breaker
+
+import "github.com/zeromicro/go-zero/core/metric"
+
+var (
+	metricDropRatio = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "breaker",
+		Subsystem: "requests",
+		Name:      "drop_ratio",
+		Help:      "breaker drop ratio.",
+		Labels:    []string{"name"},
+	})
+	metricOutcome = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "breaker",
+		Subsystem: "requests",
+		Name:      "outcome_total",
+		Help:      "breaker decision outcomes, partitioned by pass/drop/panic.",
+		Labels:    []string{"name", "outcome"},
+	})
+	metricForcePass = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "breaker",
+		Subsystem: "requests",
+		Name:      "force_pass_total",
+		Help:      "breaker calls that were force-passed via lastPass.",
+		Labels:    []string{"name"},
+	})
+)
+
+// PrometheusStatsListener is a StatsListener that exports breaker decisions
+// as Prometheus counters/gauges, so operators can alert on breaker-induced
+// drops and tune k/protection from real traffic.
+type PrometheusStatsListener struct {
+	name string
+}
+
+// NewPrometheusStatsListener returns a PrometheusStatsListener that labels
+// its metrics with the given breaker name.
+func NewPrometheusStatsListener(name string) *PrometheusStatsListener {
+	return &PrometheusStatsListener{
+		name: name,
+	}
+}
+
+// OnStats implements StatsListener.
+func (l *PrometheusStatsListener) OnStats(result StatsResult) {
+	metricDropRatio.Set(result.DropRatio, l.name)
+
+	switch result.Outcome {
+	case OutcomePass:
+		metricOutcome.Inc(l.name, "pass")
+	case OutcomeDrop:
+		metricOutcome.Inc(l.name, "drop")
+	case OutcomePanic:
+		metricOutcome.Inc(l.name, "panic")
+	}
+
+	if result.LastPass {
+		metricForcePass.Inc(l.name)
+	}
+}
@@ -0,0 +1,75 @@
+package breaker
+
+// Outcome describes what happened to a single call: accept()'s pass/drop
+// decision, or a panic of a call that was passed.
+type Outcome int
+
+const (
+	// OutcomePass means the call was allowed through.
+	OutcomePass Outcome = iota
+	// OutcomeDrop means the call was rejected by the breaker.
+	OutcomeDrop
+	// OutcomePanic means the call was allowed through but panicked.
+	OutcomePanic
+)
+
+type (
+	// StatsResult carries the internal state googleBreaker used to reach a
+	// decision, so that a StatsListener can observe drop ratios, forced
+	// passes and bucket health without inferring them from downstream errors.
+	StatsResult struct {
+		// Window is the rolling window snapshot accept() computed the
+		// decision from. It is empty for half-open probe decisions, which
+		// don't consult the window, and holds the window at the time of the
+		// panic for OutcomePanic.
+		Window windowResult
+		// DropRatio is the probability used to decide whether to drop the call.
+		// It is 0 for half-open probe decisions and for OutcomePanic.
+		DropRatio float64
+		// LastPass indicates the call was force-passed because it had been
+		// too long since the last accepted call.
+		LastPass bool
+		// Outcome is the result of the call: pass, drop or panic.
+		Outcome Outcome
+	}
+
+	// StatsListener is notified every time the breaker computes an
+	// accept/drop decision, so callers can export metrics or alert on
+	// breaker-induced drops.
+	StatsListener interface {
+		// OnStats is called with the result of a single accept() decision,
+		// and once more with OutcomePanic when a call passed by Do and its
+		// variants panics.
+		OnStats(result StatsResult)
+	}
+
+	// googleBreakerOption customizes the googleBreaker built inside NewBreaker.
+	// WithStatsListener/WithHalfOpen are the public Option values that append
+	// to it.
+	googleBreakerOption func(b *googleBreaker)
+)
+
+// WithStatsListener sets up the given listener to observe every accept()
+// decision made by the Breaker.
+func WithStatsListener(listener StatsListener) Option {
+	return func(b *breaker) {
+		b.googleOpts = append(b.googleOpts, func(gb *googleBreaker) {
+			gb.listener = listener
+		})
+	}
+}
+
+// WithHalfOpen enables the half-open probe mode: once the breaker has seen
+// halfOpenTripBuckets consecutive failing buckets, it admits at most
+// maxProbes concurrent in-flight calls instead of throttling by dropRatio,
+// and requires requiredSuccesses consecutive probe successes before closing
+// again. Without this option the breaker keeps its default behavior of
+// force-passing one request every forcePassDuration.
+func WithHalfOpen(maxProbes, requiredSuccesses int) Option {
+	return func(b *breaker) {
+		b.googleOpts = append(b.googleOpts, func(gb *googleBreaker) {
+			gb.maxProbes = int32(maxProbes)
+			gb.requiredSuccesses = int32(requiredSuccesses)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+// Package p2c implements a power-of-two-choices, EWMA-weighted grpc
+// balancer, so load is spread across healthy endpoints without the
+// coordination cost of a fully load-aware balancer.
+package p2c
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/zrpc/resolver/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+const (
+	// Name is the name registered with grpc for this balancer.
+	Name = "p2c_ewma"
+
+	decayTime   = int64(time.Second * 10)
+	forcePick   = int64(time.Second)
+	initSuccess = 1000
+	penalty     = math.MaxFloat64 / 2
+)
+
+func init() {
+	balancer.Register(&p2cBuilder{})
+}
+
+type (
+	// Config is the p2c balancer configuration, carried per-ClientConn
+	// through the dial's service config (see WithPreferZone) and parsed by
+	// p2cBuilder.ParseConfig. Unlike a package-level variable, a Config lives
+	// on the p2cBalancer instance grpc creates for each ClientConn, so two
+	// clients dialed with different zone preferences never clobber each
+	// other.
+	Config struct {
+		serviceconfig.LoadBalancingConfig `json:"-"`
+
+		// PreferZone makes the balancer prefer endpoints whose advertised
+		// zone (see zrpc/resolver/attributes) matches this value.
+		PreferZone string `json:"preferZone"`
+		// ZoneDegradeThreshold is how much worse (fractionally) the in-zone
+		// pick is allowed to score before the balancer falls back to the
+		// globally best endpoint, e.g. 0.5 tolerates the in-zone pick being
+		// up to 50% worse than the best out-of-zone one.
+		ZoneDegradeThreshold float64 `json:"zoneDegradeThreshold"`
+	}
+
+	p2cBuilder struct{}
+)
+
+func (b *p2cBuilder) Name() string {
+	return Name
+}
+
+func (b *p2cBuilder) Build(cc balancer.ClientConn, _ balancer.BuildOptions) balancer.Balancer {
+	return &p2cBalancer{
+		cc:    cc,
+		conns: make(map[string]*subConn),
+		r:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// ParseConfig implements balancer.ConfigParser, so grpc can turn the
+// loadBalancingConfig entry for Name in a dial's service config into a
+// Config that UpdateClientConnState hands to this ClientConn's balancer.
+func (b *p2cBuilder) ParseConfig(c json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	var cfg Config
+	if err := json.Unmarshal(c, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// WithPreferZone returns a grpc.DialOption selecting the p2c balancer and
+// configuring it, through this dial's own service config, to prefer zone.
+// Because the preference travels in the service config rather than a
+// package-level variable, it only affects the ClientConn it's passed to.
+func WithPreferZone(zone string, degradeThreshold float64) grpc.DialOption {
+	entry := map[string]Config{
+		Name: {
+			PreferZone:           zone,
+			ZoneDegradeThreshold: degradeThreshold,
+		},
+	}
+	raw, err := json.Marshal(map[string]any{
+		"loadBalancingConfig": []map[string]Config{entry},
+	})
+	if err != nil {
+		logx.Error(err)
+		return grpc.EmptyDialOption{}
+	}
+
+	return grpc.WithDefaultServiceConfig(string(raw))
+}
+
+// p2cBalancer is created anew by p2cBuilder.Build for every ClientConn, so
+// all its state -- including cfg -- is naturally per-ClientConn.
+type p2cBalancer struct {
+	cc    balancer.ClientConn
+	lock  sync.Mutex
+	conns map[string]*subConn
+	cfg   Config
+	r     *rand.Rand
+}
+
+func (b *p2cBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if cfg, ok := s.BalancerConfig.(Config); ok {
+		b.cfg = cfg
+	}
+
+	seen := make(map[string]bool, len(s.ResolverState.Addresses))
+	for _, addr := range s.ResolverState.Addresses {
+		addrKey := addr.Addr
+		seen[addrKey] = true
+		if c, ok := b.conns[addrKey]; ok {
+			refreshConn(c, addr)
+			continue
+		}
+
+		sc, err := b.cc.NewSubConn([]resolver.Address{addr}, balancer.NewSubConnOptions{
+			StateListener: func(state balancer.SubConnState) {
+				b.onSubConnState(addrKey, state)
+			},
+		})
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+
+		b.conns[addr.Addr] = &subConn{
+			conn:    sc,
+			addr:    addr,
+			weight:  attributes.Weight(addr.BalancerAttributes),
+			zone:    attributes.Zone(addr.BalancerAttributes),
+			success: initSuccess,
+		}
+		sc.Connect()
+	}
+
+	for addr, c := range b.conns {
+		if !seen[addr] {
+			b.cc.RemoveSubConn(c.conn)
+			delete(b.conns, addr)
+		}
+	}
+
+	b.regeneratePicker()
+
+	return nil
+}
+
+// refreshConn re-reads addr's weight/zone onto an already-connected c, so a
+// canary weight bump on an existing endpoint (the same addr, re-resolved) is
+// picked up on every UpdateClientConnState instead of being frozen at
+// whatever it was when the subConn was first created.
+func refreshConn(c *subConn, addr resolver.Address) {
+	c.addr = addr
+	c.weight = attributes.Weight(addr.BalancerAttributes)
+	c.zone = attributes.Zone(addr.BalancerAttributes)
+}
+
+func (b *p2cBalancer) onSubConnState(addr string, state balancer.SubConnState) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	c, ok := b.conns[addr]
+	if !ok {
+		return
+	}
+
+	c.ready = state.ConnectivityState == connectivity.Ready
+	b.regeneratePicker()
+}
+
+// regeneratePicker must be called with b.lock held.
+func (b *p2cBalancer) regeneratePicker() {
+	ready := make([]*subConn, 0, len(b.conns))
+	for _, c := range b.conns {
+		if c.ready {
+			ready = append(ready, c)
+		}
+	}
+
+	if len(ready) == 0 {
+		b.cc.UpdateState(balancer.State{
+			ConnectivityState: connectivity.TransientFailure,
+			Picker:            base.NewErrPicker(balancer.ErrNoSubConnAvailable),
+		})
+		return
+	}
+
+	b.cc.UpdateState(balancer.State{
+		ConnectivityState: connectivity.Ready,
+		Picker: &p2cPicker{
+			conns: ready,
+			cfg:   b.cfg,
+			r:     b.r,
+		},
+	})
+}
+
+func (b *p2cBalancer) ResolverError(error) {
+}
+
+func (b *p2cBalancer) Close() {
+}
+
+type p2cPicker struct {
+	conns []*subConn
+	cfg   Config
+	r     *rand.Rand
+	lock  sync.Mutex
+}
+
+func (p *p2cPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var chosen *subConn
+	switch len(p.conns) {
+	case 0:
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	case 1:
+		chosen = p.conns[0]
+	case 2:
+		chosen = p.choose(p.conns[0], p.conns[1])
+	default:
+		a := p.r.Intn(len(p.conns))
+		b := p.r.Intn(len(p.conns) - 1)
+		if b >= a {
+			b++
+		}
+
+		chosen = p.choose(p.conns[a], p.conns[b])
+	}
+
+	atomic.AddInt64(&chosen.inflight, 1)
+
+	return balancer.PickResult{
+		SubConn: chosen.conn,
+		Done:    p.buildDoneFunc(chosen),
+	}, nil
+}
+
+func (p *p2cPicker) buildDoneFunc(c *subConn) func(info balancer.DoneInfo) {
+	start := time.Now()
+	return func(info balancer.DoneInfo) {
+		atomic.AddInt64(&c.inflight, -1)
+
+		now := time.Now()
+		last := atomic.SwapInt64(&c.lastUpdate, now.UnixNano())
+		td := now.UnixNano() - last
+		if td < 0 {
+			td = 0
+		}
+		w := math.Exp(-float64(td) / float64(decayTime))
+
+		lag := now.Sub(start).Nanoseconds()
+		if lag < 0 {
+			lag = 0
+		}
+		oldLag := math.Float64frombits(atomic.LoadUint64(&c.lag))
+		if oldLag <= 0 {
+			w = 0
+		}
+		newLag := oldLag*w + float64(lag)*(1-w)
+		atomic.StoreUint64(&c.lag, math.Float64bits(newLag))
+
+		success := float64(initSuccess)
+		if info.Err != nil {
+			success = 0
+		}
+		oldSuccess := math.Float64frombits(atomic.LoadUint64(&c.success))
+		newSuccess := oldSuccess*w + success*(1-w)
+		atomic.StoreUint64(&c.success, math.Float64bits(newSuccess))
+	}
+}
+
+// choose picks the better of c1/c2 by EWMA load, scaled by each endpoint's
+// advertised weight, with the same-zone endpoint preferred unless its score
+// has degraded past p.cfg.ZoneDegradeThreshold. p.cfg comes from the balancer
+// that built this picker, so it reflects this ClientConn's own preference,
+// not a process-wide one.
+func (p *p2cPicker) choose(c1, c2 *subConn) *subConn {
+	now := time.Now().UnixNano()
+
+	if c1.load() > c2.load() {
+		c1, c2 = c2, c1
+	}
+
+	// c1 is the better-scored candidate at this point.
+	if zone := p.cfg.PreferZone; zone != "" {
+		if c1.zone != zone && c2.zone == zone && c2.load() <= c1.load()*(1+p.cfg.ZoneDegradeThreshold) {
+			atomic.StoreInt64(&c2.pick, now)
+			return c2
+		}
+	}
+
+	pick := atomic.LoadInt64(&c2.pick)
+	if now-pick > forcePick && atomic.CompareAndSwapInt64(&c2.pick, pick, now) {
+		return c2
+	}
+
+	atomic.StoreInt64(&c1.pick, now)
+
+	return c1
+}
+
+type subConn struct {
+	conn       balancer.SubConn
+	addr       resolver.Address
+	weight     int
+	zone       string
+	ready      bool
+	lag        uint64
+	success    uint64
+	inflight   int64
+	pick       int64
+	lastUpdate int64
+}
+
+// load returns a lower-is-better score: EWMA latency scaled by in-flight
+// requests and deflated by the endpoint's advertised weight, so a weight=200
+// endpoint is preferred twice as often as a weight=100 one at equal latency.
+func (c *subConn) load() float64 {
+	lag := math.Float64frombits(atomic.LoadUint64(&c.lag))
+	if lag <= 0 {
+		lag = 1
+	}
+
+	load := math.Sqrt(lag) * float64(atomic.LoadInt64(&c.inflight)+1)
+	if load <= 0 {
+		return penalty
+	}
+
+	weight := c.weight
+	if weight <= 0 {
+		weight = attributes.DefaultWeight
+	}
+
+	return load * float64(attributes.DefaultWeight) / float64(weight)
+}
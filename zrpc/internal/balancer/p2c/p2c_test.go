@@ -0,0 +1,55 @@
+package p2c
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/zrpc/resolver/attributes"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestSubConn_LoadWeighted(t *testing.T) {
+	light := &subConn{weight: attributes.DefaultWeight}
+	heavy := &subConn{weight: attributes.DefaultWeight * 2}
+
+	// equal latency/inflight, heavier weight must score lower (better).
+	assert.Less(t, heavy.load(), light.load())
+}
+
+func TestP2CBuilder_ParseConfig(t *testing.T) {
+	var b p2cBuilder
+	cfg, err := b.ParseConfig([]byte(`{"preferZone":"us-east-1a","zoneDegradeThreshold":0.5}`))
+	assert.NoError(t, err)
+
+	parsed, ok := cfg.(Config)
+	assert.True(t, ok)
+	assert.Equal(t, "us-east-1a", parsed.PreferZone)
+	assert.Equal(t, 0.5, parsed.ZoneDegradeThreshold)
+}
+
+func TestRefreshConn(t *testing.T) {
+	c := &subConn{addr: resolver.Address{Addr: "10.0.0.1:8080"}, weight: attributes.DefaultWeight}
+
+	bumped := resolver.Address{
+		Addr:               c.addr.Addr,
+		BalancerAttributes: attributes.New(nil, attributes.DefaultWeight*2, "us-east-1a", nil),
+	}
+	refreshConn(c, bumped)
+
+	assert.Equal(t, attributes.DefaultWeight*2, c.weight)
+	assert.Equal(t, "us-east-1a", c.zone)
+}
+
+func TestP2CPicker_ChoosePrefersZone(t *testing.T) {
+	c1 := &subConn{weight: attributes.DefaultWeight, zone: "us-east-1b"}
+	c2 := &subConn{weight: attributes.DefaultWeight, zone: "us-east-1a"}
+
+	p := &p2cPicker{
+		conns: []*subConn{c1, c2},
+		cfg:   Config{PreferZone: "us-east-1a", ZoneDegradeThreshold: 0.5},
+	}
+
+	// equal load, so the in-zone candidate (c2) should win.
+	chosen := p.choose(c1, c2)
+	assert.Equal(t, "us-east-1a", chosen.zone)
+}
@@ -0,0 +1,67 @@
+// Package attributes defines the typed keys go-zero stashes onto
+// resolver.Address.BalancerAttributes so balancers such as p2c can read
+// weight/zone/label metadata advertised by a discovery backend without
+// coupling to how that metadata was encoded on the wire.
+package attributes
+
+import "google.golang.org/grpc/attributes"
+
+type (
+	weightKey struct{}
+	zoneKey   struct{}
+	labelsKey struct{}
+)
+
+// DefaultWeight is the weight assigned to an endpoint that didn't advertise
+// one, so unweighted and weighted endpoints can be compared on the same scale.
+const DefaultWeight = 100
+
+// New builds a *attributes.Attributes carrying weight, zone and labels,
+// merging them into base (which may be nil).
+func New(base *attributes.Attributes, weight int, zone string, labels map[string]string) *attributes.Attributes {
+	if weight <= 0 {
+		weight = DefaultWeight
+	}
+
+	kv := []any{weightKey{}, weight, zoneKey{}, zone, labelsKey{}, labels}
+	if base == nil {
+		return attributes.New(kv...)
+	}
+
+	return base.WithValues(kv...)
+}
+
+// Weight returns the weight stashed on attr, or DefaultWeight if attr is nil
+// or carries none.
+func Weight(attr *attributes.Attributes) int {
+	if attr == nil {
+		return DefaultWeight
+	}
+
+	w, ok := attr.Value(weightKey{}).(int)
+	if !ok || w <= 0 {
+		return DefaultWeight
+	}
+
+	return w
+}
+
+// Zone returns the zone stashed on attr, or "" if attr is nil or carries none.
+func Zone(attr *attributes.Attributes) string {
+	if attr == nil {
+		return ""
+	}
+
+	zone, _ := attr.Value(zoneKey{}).(string)
+	return zone
+}
+
+// Labels returns the arbitrary key/value labels stashed on attr.
+func Labels(attr *attributes.Attributes) map[string]string {
+	if attr == nil {
+		return nil
+	}
+
+	labels, _ := attr.Value(labelsKey{}).(map[string]string)
+	return labels
+}
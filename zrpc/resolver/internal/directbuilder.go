@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/zrpc/resolver/internal/targets"
+	"google.golang.org/grpc/resolver"
+)
+
+// DirectScheme stands for the direct resolver scheme, whose endpoints are
+// given literally, e.g. direct://endpoint1,endpoint2, with no discovery
+// backend involved.
+const DirectScheme = "direct"
+
+type directBuilder struct{}
+
+func (b *directBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (
+	resolver.Resolver, error) {
+	endpoints := targets.GetEndpoints(target)
+	vals := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		vals = append(vals, strings.TrimSpace(endpoint))
+	}
+
+	addrs := buildAddresses(DirectScheme, vals)
+	if err := cc.UpdateState(resolver.State{
+		Addresses: addrs,
+	}); err != nil {
+		logx.Error(err)
+	}
+
+	return &nopResolver{cc: cc}, nil
+}
+
+func (b *directBuilder) Scheme() string {
+	return DirectScheme
+}
@@ -1,14 +1,21 @@
 package internal
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/discov"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/zrpc/resolver/attributes"
 	"github.com/zeromicro/go-zero/zrpc/resolver/internal/targets"
 	"google.golang.org/grpc/resolver"
 )
 
+// attrSepChar separates the address from its weight/zone/label metadata,
+// e.g. "host:port|weight=10,zone=us-east-1a". Values with no attrSepChar
+// are treated as plain addresses, so existing etcd values keep working.
+const attrSepChar = '|'
+
 type discovBuilder struct{}
 
 func (b *discovBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (
@@ -23,12 +30,7 @@ func (b *discovBuilder) Build(target resolver.Target, cc resolver.ClientConn, _
 
 	update := func() {
 		vals := subset(sub.Values(), subsetSize)
-		addrs := make([]resolver.Address, 0, len(vals))
-		for _, val := range vals {
-			addrs = append(addrs, resolver.Address{
-				Addr: val,
-			})
-		}
+		addrs := buildAddresses(DiscovScheme, vals)
 		// 调用cc.UpdateState更新服务状态
 		if err := cc.UpdateState(resolver.State{
 			Addresses: addrs,
@@ -47,3 +49,42 @@ func (b *discovBuilder) Build(target resolver.Target, cc resolver.ClientConn, _
 func (b *discovBuilder) Scheme() string {
 	return DiscovScheme
 }
+
+// parseAddr turns one etcd value into a resolver.Address, optionally
+// decoding weight/zone/label metadata appended after attrSepChar, e.g.
+// "host:port|weight=10,zone=us-east-1a,canary=true". This lets operators do
+// canary weighting and zone-aware routing through discovery, so the p2c
+// balancer can read it back via the zrpc/resolver/attributes package.
+func parseAddr(val string) resolver.Address {
+	addr, rest, ok := strings.Cut(val, string(attrSepChar))
+	if !ok {
+		return resolver.Address{Addr: addr}
+	}
+
+	var weight int
+	var zone string
+	labels := make(map[string]string)
+
+	for _, pair := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+
+		switch k {
+		case "weight":
+			if w, err := strconv.Atoi(v); err == nil {
+				weight = w
+			}
+		case "zone":
+			zone = v
+		default:
+			labels[k] = v
+		}
+	}
+
+	return resolver.Address{
+		Addr:               addr,
+		BalancerAttributes: attributes.New(nil, weight, zone, labels),
+	}
+}
@@ -0,0 +1,12 @@
+package internal
+
+import "google.golang.org/grpc/resolver"
+
+// RegisterResolver registers all the resolver.Builder implementations go-zero
+// ships with grpc, so Target schemes like discov:// and srv:// are usable in
+// RpcClientConf without callers needing to import internal directly.
+func RegisterResolver() {
+	resolver.Register(&discovBuilder{})
+	resolver.Register(&srvBuilder{})
+	resolver.Register(&directBuilder{})
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/zrpc/resolver/attributes"
+	"github.com/zeromicro/go-zero/zrpc/resolver/internal/targets"
+	"google.golang.org/grpc/resolver"
+)
+
+// SrvScheme stands for the dns srv resolver scheme, e.g.
+// srv://_grpc._tcp.myservice.consul/8080. The endpoint segment ("8080"
+// above) supplies the port to dial when SRV lookup finds nothing and the
+// resolver falls back to a plain A/AAAA lookup, since bare host records
+// carry no port of their own.
+const SrvScheme = "srv"
+
+// defaultSrvTTL is the fixed re-resolution cadence used for both the SRV and
+// the A/AAAA fallback lookup. The stdlib net resolver doesn't surface the
+// DNS record TTL on *net.SRV/*net.IPAddr, so re-resolution is a fixed
+// interval rather than driven by the record's actual TTL; a resolver built
+// on a DNS client that exposes the TTL (e.g. miekg/dns) would be needed to
+// honor it.
+const defaultSrvTTL = time.Second * 30
+
+// srvPriorityLabel is the label key the SRV record's Priority is stashed
+// under, alongside Weight, via the shared zrpc/resolver/attributes package --
+// the same mechanism discovBuilder and p2c use, so SRV-sourced weight
+// actually reaches the p2c balancer instead of sitting in a parallel,
+// unread attribute type.
+const srvPriorityLabel = "priority"
+
+// srvBuilder resolves gRPC targets published as DNS SRV records, e.g.
+// Consul or Kubernetes headless services (_grpc._tcp.<svc>).
+type srvBuilder struct{}
+
+func (b *srvBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (
+	resolver.Resolver, error) {
+	var fallbackPort string
+	if endpoints := targets.GetEndpoints(target); len(endpoints) > 0 {
+		fallbackPort = endpoints[0]
+	}
+
+	r := &srvResolver{
+		name:         targets.GetAuthority(target),
+		fallbackPort: fallbackPort,
+		cc:           cc,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.resolve(ctx)
+	go r.watch(ctx)
+
+	return r, nil
+}
+
+func (b *srvBuilder) Scheme() string {
+	return SrvScheme
+}
+
+type srvResolver struct {
+	lock sync.Mutex
+	// name is the SRV name to resolve, e.g. _grpc._tcp.myservice.consul.
+	name string
+	// fallbackPort is dialed when SRV lookup returns nothing and the
+	// resolver falls back to a plain A/AAAA lookup against the bare service
+	// name. Required for that path to produce dialable addresses at all.
+	fallbackPort string
+	cc           resolver.ClientConn
+	cancel       context.CancelFunc
+}
+
+func (r *srvResolver) watch(ctx context.Context) {
+	ttl := defaultSrvTTL
+	for {
+		select {
+		case <-time.After(ttl):
+			ttl = r.resolve(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resolve performs one SRV lookup, falling back to a plain A/AAAA lookup
+// against the bare service name when no SRV records are published, and
+// pushes the result through the same cc.UpdateState flow discovBuilder
+// uses. It always re-resolves on defaultSrvTTL, see its doc comment.
+func (r *srvResolver) resolve(ctx context.Context) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	service, proto, name, ok := splitSrvName(r.name)
+	// name falls back to the raw target when it isn't in _service._proto.name
+	// form, so the A/AAAA lookup below always has something to query.
+	if !ok {
+		name = r.name
+	}
+
+	var addrs []resolver.Address
+	if ok {
+		addrs = r.lookupSRV(ctx, service, proto, name)
+	}
+	if len(addrs) == 0 {
+		addrs = r.lookupHost(ctx, name)
+	}
+
+	if err := r.cc.UpdateState(resolver.State{
+		Addresses: addrs,
+	}); err != nil {
+		logx.Error(err)
+	}
+
+	return defaultSrvTTL
+}
+
+func (r *srvResolver) lookupSRV(ctx context.Context, service, proto, name string) []resolver.Address {
+	_, srvs, err := net.DefaultResolver.LookupSRV(ctx, service, proto, name)
+	if err != nil {
+		logx.Error(err)
+		return nil
+	}
+
+	return addrsFromSRV(srvs)
+}
+
+// lookupHost resolves name (the bare service name, with any _service._proto.
+// prefix already stripped by splitSrvName) via plain A/AAAA lookup, dialing
+// fallbackPort on every address since A/AAAA records carry no port. If no
+// fallbackPort was configured on the target, there is nothing dialable to
+// build, so this logs and returns no addresses rather than shipping a
+// bare-IP address grpc can never dial.
+func (r *srvResolver) lookupHost(ctx context.Context, name string) []resolver.Address {
+	if r.fallbackPort == "" {
+		logx.Errorf("srv resolver: no fallback port configured for %q, "+
+			"cannot build a dialable A/AAAA fallback address; "+
+			"use a target like srv://%s/<port>", r.name, r.name)
+		return nil
+	}
+
+	ips, err := net.DefaultResolver.LookupHost(ctx, name)
+	if err != nil {
+		logx.Error(err)
+		return nil
+	}
+
+	return addrsFromIPs(ips, r.fallbackPort)
+}
+
+func (r *srvResolver) ResolveNow(resolver.ResolveNowOptions) {
+}
+
+func (r *srvResolver) Close() {
+	r.cancel()
+}
+
+// addrsFromSRV turns SRV records into resolver.Address entries, stashing
+// each record's Weight/Priority through the shared attributes package so
+// the p2c balancer's weighted picks actually see them.
+func addrsFromSRV(srvs []*net.SRV) []resolver.Address {
+	addrs := make([]resolver.Address, 0, len(srvs))
+	for _, srv := range srvs {
+		addrs = append(addrs, resolver.Address{
+			Addr: net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))),
+			BalancerAttributes: attributes.New(nil, int(srv.Weight), "", map[string]string{
+				srvPriorityLabel: strconv.Itoa(int(srv.Priority)),
+			}),
+		})
+	}
+
+	return addrs
+}
+
+// addrsFromIPs turns plain A/AAAA results into resolver.Address entries,
+// pairing each IP with port since net.LookupHost returns bare IPs.
+func addrsFromIPs(ips []string, port string) []resolver.Address {
+	addrs := make([]resolver.Address, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(ip, port)})
+	}
+
+	return addrs
+}
+
+// splitSrvName splits a _service._proto.name SRV target into its parts,
+// e.g. _grpc._tcp.myservice.consul -> ("grpc", "tcp", "myservice.consul").
+func splitSrvName(target string) (service, proto, name string, ok bool) {
+	parts := strings.SplitN(target, ".", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "_") || !strings.HasPrefix(parts[1], "_") {
+		return "", "", "", false
+	}
+
+	return strings.TrimPrefix(parts[0], "_"), strings.TrimPrefix(parts[1], "_"), parts[2], true
+}
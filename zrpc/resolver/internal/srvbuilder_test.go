@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/zrpc/resolver/attributes"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestSplitSrvName(t *testing.T) {
+	tests := []struct {
+		target      string
+		wantService string
+		wantProto   string
+		wantName    string
+		wantOk      bool
+	}{
+		{"_grpc._tcp.myservice.consul", "grpc", "tcp", "myservice.consul", true},
+		{"_grpc._tcp.svc", "grpc", "tcp", "svc", true},
+		{"myservice.consul", "", "", "", false},
+		{"_grpc.myservice.consul", "", "", "", false},
+	}
+
+	for _, test := range tests {
+		service, proto, name, ok := splitSrvName(test.target)
+		assert.Equal(t, test.wantOk, ok)
+		if test.wantOk {
+			assert.Equal(t, test.wantService, service)
+			assert.Equal(t, test.wantProto, proto)
+			assert.Equal(t, test.wantName, name)
+		}
+	}
+}
+
+func TestSrvBuilder_Scheme(t *testing.T) {
+	var b srvBuilder
+	assert.Equal(t, SrvScheme, b.Scheme())
+}
+
+func TestAddrsFromIPs(t *testing.T) {
+	addrs := addrsFromIPs([]string{"10.0.0.1", "10.0.0.2"}, "8080")
+
+	assert.Equal(t, []resolver.Address{
+		{Addr: "10.0.0.1:8080"},
+		{Addr: "10.0.0.2:8080"},
+	}, addrs)
+}
+
+func TestAddrsFromSRV(t *testing.T) {
+	srvs := []*net.SRV{
+		{Target: "node1.myservice.consul.", Port: 8080, Priority: 10, Weight: 200},
+	}
+
+	addrs := addrsFromSRV(srvs)
+
+	assert.Len(t, addrs, 1)
+	assert.Equal(t, "node1.myservice.consul:8080", addrs[0].Addr)
+	assert.Equal(t, 200, attributes.Weight(addrs[0].BalancerAttributes))
+}
+
+func TestSrvResolver_LookupHostNoFallbackPort(t *testing.T) {
+	r := &srvResolver{name: "_grpc._tcp.myservice.consul"}
+
+	// With no fallbackPort configured, the A/AAAA path has no port to pair
+	// with a bare IP, so it must return nothing rather than an undialable
+	// bare-IP address.
+	assert.Empty(t, r.lookupHost(context.Background(), "myservice.consul"))
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// EndpointTransformer rewrites the raw values discovered for a target (etcd
+// values for discov://, literal endpoints for direct://) into fully-formed
+// resolver.Address entries, including ServerName (for TLS SNI) and
+// Attributes. This is the injection point users running behind an envoy
+// sidecar or mTLS need to rewrite discovered addresses, instead of forking
+// the resolver.
+type EndpointTransformer func(endpoints []string) []resolver.Address
+
+var (
+	transformerLock   sync.RWMutex
+	endpointTransform = make(map[string]EndpointTransformer)
+)
+
+// RegisterEndpointTransformer installs fn as the EndpointTransformer for the
+// given resolver scheme, replacing any previously registered one.
+func RegisterEndpointTransformer(scheme string, fn EndpointTransformer) {
+	transformerLock.Lock()
+	defer transformerLock.Unlock()
+	endpointTransform[scheme] = fn
+}
+
+// getEndpointTransformer returns the EndpointTransformer registered for
+// scheme, if any.
+func getEndpointTransformer(scheme string) (EndpointTransformer, bool) {
+	transformerLock.RLock()
+	defer transformerLock.RUnlock()
+	fn, ok := endpointTransform[scheme]
+	return fn, ok
+}
+
+// buildAddresses turns raw discovered values into resolver.Address entries,
+// deferring to the scheme's registered EndpointTransformer when present and
+// falling back to the default weight/zone/label parsing otherwise.
+func buildAddresses(scheme string, vals []string) []resolver.Address {
+	if fn, ok := getEndpointTransformer(scheme); ok {
+		return fn(vals)
+	}
+
+	addrs := make([]resolver.Address, 0, len(vals))
+	for _, val := range vals {
+		addrs = append(addrs, parseAddr(val))
+	}
+
+	return addrs
+}
@@ -0,0 +1,7 @@
+package resolver
+
+import "github.com/zeromicro/go-zero/zrpc/resolver/internal"
+
+func init() {
+	internal.RegisterResolver()
+}
@@ -0,0 +1,16 @@
+package resolver
+
+import (
+	"github.com/zeromicro/go-zero/zrpc/resolver/internal"
+	"google.golang.org/grpc/resolver"
+)
+
+// RegisterEndpointTransformer installs fn as the EndpointTransformer for the
+// given resolver scheme (internal.DiscovScheme or internal.DirectScheme).
+// fn receives the raw discovered values and returns fully-formed
+// resolver.Address entries, including ServerName (for TLS SNI) and
+// Attributes, so callers can centralize mTLS/multi-tenant routing policies
+// instead of forking the resolver.
+func RegisterEndpointTransformer(scheme string, fn func(endpoints []string) []resolver.Address) {
+	internal.RegisterEndpointTransformer(scheme, fn)
+}